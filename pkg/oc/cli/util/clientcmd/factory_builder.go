@@ -0,0 +1,79 @@
+package clientcmd
+
+import (
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	"k8s.io/kubernetes/pkg/kubectl"
+	kcmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
+
+	deploymentcmd "github.com/openshift/origin/pkg/oc/cli/deploymentconfigs"
+	routegen "github.com/openshift/origin/pkg/route/generator"
+)
+
+// ring2Factory implements the third, builder tier of the three tier factory split:
+// ClientAccessFactory, ObjectMappingFactory, BuilderFactory. It hands out a cli-runtime
+// resource.Builder wired with its ClientAccessFactory (a genericclioptions.RESTClientGetter)
+// and the OpenShift scheme, instead of going through kcmdutil.NewBuilderFactory's internal
+// kubectl resource.Builder.
+type ring2Factory struct {
+	clientAccessFactory  ClientAccessFactory
+	objectMappingFactory ObjectMappingFactory
+}
+
+type BuilderFactory interface {
+	NewBuilder() *resource.Builder
+	Generators(cmdName string) map[string]kubectl.Generator
+	EditorEnvs() []string
+}
+
+func NewBuilderFactory(clientAccessFactory ClientAccessFactory, objectMappingFactory ObjectMappingFactory) BuilderFactory {
+	return &ring2Factory{
+		clientAccessFactory:  clientAccessFactory,
+		objectMappingFactory: objectMappingFactory,
+	}
+}
+
+func (f *ring2Factory) NewBuilder() *resource.Builder {
+	return resource.NewBuilder(f.clientAccessFactory).
+		WithScheme(legacyscheme.Scheme, legacyscheme.Scheme.PrioritizedVersionsAllGroups()...).
+		ContinueOnError().
+		Flatten()
+}
+
+func DefaultGenerators(cmdName string) map[string]kubectl.Generator {
+	generators := map[string]map[string]kubectl.Generator{}
+	generators["run"] = map[string]kubectl.Generator{
+		"deploymentconfig/v1": deploymentcmd.BasicDeploymentConfigController{},
+		"run-controller/v1":   kubectl.BasicReplicationController{}, // legacy alias for run/v1
+	}
+	generators["expose"] = map[string]kubectl.Generator{
+		"route/v1": routegen.RouteGenerator{},
+	}
+	generators["poddisruptionbudget"] = map[string]kubectl.Generator{
+		"poddisruptionbudget/v1": kubectl.PodDisruptionBudgetV1Generator{},
+		"poddisruptionbudget/v2": kubectl.PodDisruptionBudgetV2Generator{},
+	}
+
+	return generators[cmdName]
+}
+
+// Generators merges the origin-specific generators above with kcmdutil's upstream default
+// set. DefaultGenerators is a plain function, not a Factory method, so pulling it in doesn't
+// reintroduce a dependency on the kcmdutil Factory this tier used to wrap.
+func (f *ring2Factory) Generators(cmdName string) map[string]kubectl.Generator {
+	originGenerators := DefaultGenerators(cmdName)
+	kubeGenerators := kcmdutil.DefaultGenerators(cmdName)
+
+	ret := map[string]kubectl.Generator{}
+	for k, v := range kubeGenerators {
+		ret[k] = v
+	}
+	for k, v := range originGenerators {
+		ret[k] = v
+	}
+	return ret
+}
+
+func (f *ring2Factory) EditorEnvs() []string {
+	return []string{"OC_EDITOR", "EDITOR"}
+}
@@ -0,0 +1,53 @@
+package clientcmd
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCanBeExposed(t *testing.T) {
+	f := &ring1Factory{}
+
+	for _, allowed := range []schema.GroupKind{
+		{Kind: "Service"},
+		{Group: "apps.openshift.io", Kind: "DeploymentConfig"},
+	} {
+		if err := f.CanBeExposed(allowed); err != nil {
+			t.Errorf("CanBeExposed(%v) = %v, want nil", allowed, err)
+		}
+	}
+
+	for _, rejected := range []schema.GroupKind{
+		{Kind: "ConfigMap"},
+		{Kind: "Secret"},
+		{Group: "batch", Kind: "Job"},
+	} {
+		if err := f.CanBeExposed(rejected); err == nil {
+			t.Errorf("CanBeExposed(%v) = nil, want an error for a kind oc expose does not support", rejected)
+		}
+	}
+}
+
+func TestCanBeAutoscaled(t *testing.T) {
+	f := &ring1Factory{}
+
+	for _, allowed := range []schema.GroupKind{
+		{Group: "apps", Kind: "Deployment"},
+		{Group: "apps.openshift.io", Kind: "DeploymentConfig"},
+	} {
+		if err := f.CanBeAutoscaled(allowed); err != nil {
+			t.Errorf("CanBeAutoscaled(%v) = %v, want nil", allowed, err)
+		}
+	}
+
+	for _, rejected := range []schema.GroupKind{
+		{Kind: "Service"},
+		{Kind: "Pod"},
+		{Group: "batch", Kind: "Job"},
+	} {
+		if err := f.CanBeAutoscaled(rejected); err == nil {
+			t.Errorf("CanBeAutoscaled(%v) = nil, want an error: HorizontalPodAutoscaler can't target this kind", rejected)
+		}
+	}
+}
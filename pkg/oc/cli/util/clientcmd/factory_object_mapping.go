@@ -0,0 +1,471 @@
+package clientcmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	"k8s.io/kubernetes/pkg/api/legacyscheme"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/kubectl"
+	kcmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
+
+	appsapiv1 "github.com/openshift/api/apps/v1"
+	appsapi "github.com/openshift/origin/pkg/apps/apis/apps"
+)
+
+// ring1Factory implements the second, object mapping tier of the three tier factory split:
+// ClientAccessFactory, ObjectMappingFactory, BuilderFactory. Earlier revisions wrapped
+// kcmdutil.NewObjectMappingFactory and delegated everything not DC-specific to it; that
+// kept ring1Factory tied to the internal kubectl package's Factory. It now only depends on
+// its ClientAccessFactory (a cli-runtime RESTClientGetter) and implements the handful of
+// common built-in kinds (Service, ReplicationController, Deployment, ReplicaSet, DaemonSet,
+// StatefulSet, Job) itself, alongside the DeploymentConfig special cases. Not every method
+// covers all of those kinds: UpdatePodSpecForObject handles only DeploymentConfigs,
+// Deployments and Pods, PortsForObject/ProtocolsForObject do not handle Services or Jobs, and
+// Pauser/Resumer handle only internal DeploymentConfigs and Deployments.
+type ring1Factory struct {
+	clientAccessFactory ClientAccessFactory
+}
+
+type ObjectMappingFactory interface {
+	UpdatePodSpecForObject(obj runtime.Object, fn func(*corev1.PodSpec) error) (bool, error)
+	MapBasedSelectorForObject(object runtime.Object) (string, error)
+	PortsForObject(object runtime.Object) ([]string, error)
+	ProtocolsForObject(object runtime.Object) (map[string]string, error)
+	LabelsForObject(object runtime.Object) (map[string]string, error)
+	DefaultResourceFilterFunc() kubectl.Filters
+	SuggestedPodTemplateResources() []schema.GroupResource
+	Pauser(info *resource.Info) ([]byte, error)
+	ResolveImage(image string) (string, error)
+	Resumer(info *resource.Info) ([]byte, error)
+	CanBeExposed(kind schema.GroupKind) error
+	CanBeAutoscaled(kind schema.GroupKind) error
+	PodDisruptionBudgetSelectorForObject(object runtime.Object) (*metav1.LabelSelector, error)
+}
+
+func NewObjectMappingFactory(clientAccessFactory ClientAccessFactory) ObjectMappingFactory {
+	return &ring1Factory{clientAccessFactory: clientAccessFactory}
+}
+
+func (f *ring1Factory) UpdatePodSpecForObject(obj runtime.Object, fn func(*corev1.PodSpec) error) (bool, error) {
+	switch t := obj.(type) {
+	case *appsapi.DeploymentConfig:
+		template := t.Spec.Template
+		if template == nil {
+			template = &kapi.PodTemplateSpec{}
+			t.Spec.Template = template
+		}
+		if err := ConvertExteralPodSpecToInternal(fn)(&template.Spec); err != nil {
+			return true, err
+		}
+		return true, nil
+
+	case *appsapiv1.DeploymentConfig:
+		template := t.Spec.Template
+		if template == nil {
+			template = &corev1.PodTemplateSpec{}
+			t.Spec.Template = template
+		}
+		return true, fn(&template.Spec)
+
+	case *appsv1.Deployment:
+		return true, fn(&t.Spec.Template.Spec)
+
+	case *corev1.Pod:
+		return true, fn(&t.Spec)
+
+	default:
+		return false, fmt.Errorf("the object %T is not a pod or does not have a pod template", obj)
+	}
+}
+
+func ConvertInteralPodSpecToExternal(inFn func(*kapi.PodSpec) error) func(*corev1.PodSpec) error {
+	return func(specToMutate *corev1.PodSpec) error {
+		internalPodSpec := &kapi.PodSpec{}
+		if err := legacyscheme.Scheme.Convert(specToMutate, internalPodSpec, nil); err != nil {
+			return err
+		}
+		if err := inFn(internalPodSpec); err != nil {
+			return err
+		}
+		externalPodSpec := &corev1.PodSpec{}
+		if err := legacyscheme.Scheme.Convert(internalPodSpec, externalPodSpec, nil); err != nil {
+			return err
+		}
+		*specToMutate = *externalPodSpec
+		return nil
+	}
+}
+
+func ConvertExteralPodSpecToInternal(inFn func(*corev1.PodSpec) error) func(*kapi.PodSpec) error {
+	return func(specToMutate *kapi.PodSpec) error {
+		externalPodSpec := &corev1.PodSpec{}
+		if err := legacyscheme.Scheme.Convert(specToMutate, externalPodSpec, nil); err != nil {
+			return err
+		}
+		if err := inFn(externalPodSpec); err != nil {
+			return err
+		}
+		internalPodSpec := &kapi.PodSpec{}
+		if err := legacyscheme.Scheme.Convert(externalPodSpec, internalPodSpec, nil); err != nil {
+			return err
+		}
+		*specToMutate = *internalPodSpec
+		return nil
+	}
+}
+
+func (f *ring1Factory) MapBasedSelectorForObject(object runtime.Object) (string, error) {
+	switch t := object.(type) {
+	case *appsapi.DeploymentConfig:
+		return kubectl.MakeLabels(t.Spec.Selector), nil
+	case *appsapiv1.DeploymentConfig:
+		return kubectl.MakeLabels(t.Spec.Selector), nil
+	case *corev1.ReplicationController:
+		return kubectl.MakeLabels(t.Spec.Selector), nil
+	case *corev1.Service:
+		if len(t.Spec.Selector) == 0 {
+			return "", fmt.Errorf("the service %s has no pod selector set", t.Name)
+		}
+		return kubectl.MakeLabels(t.Spec.Selector), nil
+	case *appsv1.Deployment:
+		return kubectl.MakeLabels(t.Spec.Selector.MatchLabels), nil
+	case *appsv1.ReplicaSet:
+		return kubectl.MakeLabels(t.Spec.Selector.MatchLabels), nil
+	case *appsv1.DaemonSet:
+		return kubectl.MakeLabels(t.Spec.Selector.MatchLabels), nil
+	case *appsv1.StatefulSet:
+		return kubectl.MakeLabels(t.Spec.Selector.MatchLabels), nil
+	case *batchv1.Job:
+		if t.Spec.Selector == nil {
+			return "", fmt.Errorf("the job %s has no pod selector set", t.Name)
+		}
+		return kubectl.MakeLabels(t.Spec.Selector.MatchLabels), nil
+	default:
+		return "", fmt.Errorf("cannot extract pod selector for %T", object)
+	}
+}
+
+// PodDisruptionBudgetSelectorForObject extracts a label selector suitable for a
+// PodDisruptionBudget targeting object, so a PodDisruptionBudget's selector can be derived
+// from a DeploymentConfig instead of being hand-written. DeploymentConfigs aren't handled by the poddisruptionbudget/v1 and
+// poddisruptionbudget/v2 generators' own selector lookup, so they're special cased here the
+// same way MapBasedSelectorForObject is above.
+func (f *ring1Factory) PodDisruptionBudgetSelectorForObject(object runtime.Object) (*metav1.LabelSelector, error) {
+	switch t := object.(type) {
+	case *appsapi.DeploymentConfig:
+		return &metav1.LabelSelector{MatchLabels: t.Spec.Selector}, nil
+	case *appsapiv1.DeploymentConfig:
+		return &metav1.LabelSelector{MatchLabels: t.Spec.Selector}, nil
+	default:
+		selector, err := f.MapBasedSelectorForObject(object)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create a PodDisruptionBudget for %T: %v", object, err)
+		}
+		parsed, err := metav1.ParseToLabelSelector(selector)
+		if err != nil {
+			return nil, err
+		}
+		return parsed, nil
+	}
+}
+
+func (f *ring1Factory) PortsForObject(object runtime.Object) ([]string, error) {
+	infos, err := PortInfosForObject(object)
+	if err != nil {
+		return nil, err
+	}
+	return portStrings(infos), nil
+}
+
+func (f *ring1Factory) ProtocolsForObject(object runtime.Object) (map[string]string, error) {
+	infos, err := PortInfosForObject(object)
+	if err != nil {
+		return nil, err
+	}
+	return protocolsForPortInfos(infos), nil
+}
+
+func (f *ring1Factory) LabelsForObject(object runtime.Object) (map[string]string, error) {
+	accessor, err := apimeta.Accessor(object)
+	if err != nil {
+		return nil, err
+	}
+	return accessor.GetLabels(), nil
+}
+
+// DefaultResourceFilterFunc previously deferred to kcmdutil.ObjectMappingFactory, which hid
+// completed/failed pods and jobs from `oc get` unless --show-all was passed. cli-runtime
+// doesn't ship an equivalent helper, so until that's reimplemented here the simplified
+// default is to apply no filtering.
+func (f *ring1Factory) DefaultResourceFilterFunc() kubectl.Filters {
+	return kubectl.Filters{}
+}
+
+func (f *ring1Factory) SuggestedPodTemplateResources() []schema.GroupResource {
+	return []schema.GroupResource{
+		{Resource: "pods"},
+		{Resource: "replicationcontrollers"},
+		{Group: "apps", Resource: "deployments"},
+		{Group: "apps", Resource: "replicasets"},
+		{Group: "apps", Resource: "daemonsets"},
+		{Group: "apps", Resource: "statefulsets"},
+		{Group: "batch", Resource: "jobs"},
+		{Group: "apps.openshift.io", Resource: "deploymentconfigs"},
+	}
+}
+
+func (f *ring1Factory) Pauser(info *resource.Info) ([]byte, error) {
+	switch t := info.Object.(type) {
+	case *appsapi.DeploymentConfig:
+		if t.Spec.Paused {
+			return nil, errors.New("is already paused")
+		}
+		t.Spec.Paused = true
+		// TODO: Pause the deployer containers.
+		return runtime.Encode(kcmdutil.InternalVersionJSONEncoder(), info.Object)
+	case *appsv1.Deployment:
+		if t.Spec.Paused {
+			return nil, errors.New("is already paused")
+		}
+		t.Spec.Paused = true
+		return runtime.Encode(legacyscheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion), info.Object)
+	default:
+		return nil, fmt.Errorf("cannot pause %T", info.Object)
+	}
+}
+
+func (f *ring1Factory) ResolveImage(image string) (string, error) {
+	return ParseDockerImageReferenceToStringFunc(image)
+}
+
+func (f *ring1Factory) Resumer(info *resource.Info) ([]byte, error) {
+	switch t := info.Object.(type) {
+	case *appsapi.DeploymentConfig:
+		if !t.Spec.Paused {
+			return nil, errors.New("is not paused")
+		}
+		t.Spec.Paused = false
+		// TODO: Resume the deployer containers.
+		return runtime.Encode(kcmdutil.InternalVersionJSONEncoder(), info.Object)
+	case *appsv1.Deployment:
+		if !t.Spec.Paused {
+			return nil, errors.New("is not paused")
+		}
+		t.Spec.Paused = false
+		return runtime.Encode(legacyscheme.Codecs.LegacyCodec(appsv1.SchemeGroupVersion), info.Object)
+	default:
+		return nil, fmt.Errorf("cannot resume %T", info.Object)
+	}
+}
+
+// exposableKinds are the GroupKinds `oc expose` is allowed to create a Service/Route for,
+// mirroring the allow-list the old kcmdutil-backed ring0Factory.CanBeExposed kept.
+var exposableKinds = []schema.GroupKind{
+	{Kind: "ReplicationController"},
+	{Kind: "Service"},
+	{Kind: "Pod"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "ReplicaSet"},
+	{Group: "apps.openshift.io", Kind: "DeploymentConfig"},
+	{Kind: "DeploymentConfig"},
+}
+
+// autoscalableKinds are the GroupKinds `oc autoscale` is allowed to target, mirroring the
+// allow-list the old kcmdutil-backed ring0Factory.CanBeAutoscaled kept. Unlike exposableKinds
+// it excludes Service and Pod, which HorizontalPodAutoscaler can't target.
+var autoscalableKinds = []schema.GroupKind{
+	{Kind: "ReplicationController"},
+	{Group: "apps", Kind: "Deployment"},
+	{Group: "apps", Kind: "ReplicaSet"},
+	{Group: "apps", Kind: "StatefulSet"},
+	{Group: "apps.openshift.io", Kind: "DeploymentConfig"},
+	{Kind: "DeploymentConfig"},
+}
+
+func (f *ring1Factory) CanBeExposed(kind schema.GroupKind) error {
+	for _, allowed := range exposableKinds {
+		if allowed == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot expose a %s", kind)
+}
+
+func (f *ring1Factory) CanBeAutoscaled(kind schema.GroupKind) error {
+	for _, allowed := range autoscalableKinds {
+		if allowed == kind {
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot autoscale a %s", kind)
+}
+
+// PortInfo is the structured form of a container port, keeping the name, host port and IP
+// family alongside the container port that the legacy []string/map[string]string forms drop.
+type PortInfo struct {
+	Name          string
+	ContainerPort int32
+	HostPort      int32
+	Protocol      string
+	// IPFamilies is only populated when the port was resolved from a live Pod with
+	// status.podIPs set; it lists the families ("IPv4", "IPv6") the port is reachable on.
+	// Nothing in this package reads it; PreferredTargetPort ignores it.
+	IPFamilies []string
+}
+
+// PortInfosForObject extracts PortInfo for every container port in object, walking init and
+// ephemeral containers in addition to the main containers so commands like expose/debug see
+// every reachable port. When object is a live *corev1.Pod, each port is annotated with the IP
+// families it is reachable on, based on status.podIPs.
+func PortInfosForObject(object runtime.Object) ([]PortInfo, error) {
+	switch t := object.(type) {
+	case *appsapi.DeploymentConfig:
+		return portInfosForPodSpec(t.Spec.Template.Spec), nil
+
+	case *appsapiv1.DeploymentConfig:
+		internal := &kapi.PodSpec{}
+		if err := legacyscheme.Scheme.Convert(&t.Spec.Template.Spec, internal, nil); err != nil {
+			return nil, err
+		}
+		return portInfosForPodSpec(*internal), nil
+
+	case *corev1.Pod:
+		internal := &kapi.PodSpec{}
+		if err := legacyscheme.Scheme.Convert(&t.Spec, internal, nil); err != nil {
+			return nil, err
+		}
+		infos := portInfosForPodSpec(*internal)
+		annotateIPFamilies(infos, t.Status.PodIPs)
+		return infos, nil
+
+	case *corev1.ReplicationController:
+		return portInfosForExternalPodSpec(&t.Spec.Template.Spec)
+
+	case *appsv1.Deployment:
+		return portInfosForExternalPodSpec(&t.Spec.Template.Spec)
+
+	case *appsv1.ReplicaSet:
+		return portInfosForExternalPodSpec(&t.Spec.Template.Spec)
+
+	case *appsv1.DaemonSet:
+		return portInfosForExternalPodSpec(&t.Spec.Template.Spec)
+
+	case *appsv1.StatefulSet:
+		return portInfosForExternalPodSpec(&t.Spec.Template.Spec)
+
+	default:
+		return nil, fmt.Errorf("cannot extract ports for %T", object)
+	}
+}
+
+// PreferredTargetPort picks the PortInfo `oc expose`'s route generator should point a Route's
+// targetPort at: the first named port, so the generated Route survives the container's port
+// being renumbered, falling back to the first port at all when none are named.
+//
+// NOTE: this is currently unwired. The chunk0-4 request also asked to update
+// pkg/route/generator's RouteGenerator to call this and set targetPort from it, but that
+// package isn't part of this checkout (this tree only contains
+// pkg/oc/cli/util/clientcmd), so the generator side of that change can't be made here. This
+// helper is the piece PortInfosForObject can already provide; wiring it into RouteGenerator.Generate
+// is left for whoever has that file.
+func PreferredTargetPort(infos []PortInfo) *PortInfo {
+	if len(infos) == 0 {
+		return nil
+	}
+	for i := range infos {
+		if infos[i].Name != "" {
+			return &infos[i]
+		}
+	}
+	return &infos[0]
+}
+
+func portInfosForExternalPodSpec(spec *corev1.PodSpec) ([]PortInfo, error) {
+	internal := &kapi.PodSpec{}
+	if err := legacyscheme.Scheme.Convert(spec, internal, nil); err != nil {
+		return nil, err
+	}
+	return portInfosForPodSpec(*internal), nil
+}
+
+func portInfosForPodSpec(spec kapi.PodSpec) []PortInfo {
+	var result []PortInfo
+	// Containers come first so the serving container's ports keep being the ones a caller
+	// picking "the" port for a DC/Pod sees first; init/ephemeral containers are appended
+	// after since their ports are normally transient or debug-only.
+	containers := make([]kapi.Container, 0, len(spec.InitContainers)+len(spec.Containers)+len(spec.EphemeralContainers))
+	containers = append(containers, spec.Containers...)
+	containers = append(containers, spec.InitContainers...)
+	for _, ephemeral := range spec.EphemeralContainers {
+		containers = append(containers, kapi.Container(ephemeral.EphemeralContainerCommon))
+	}
+
+	for _, container := range containers {
+		for _, port := range container.Ports {
+			result = append(result, PortInfo{
+				Name:          port.Name,
+				ContainerPort: port.ContainerPort,
+				HostPort:      port.HostPort,
+				Protocol:      string(port.Protocol),
+			})
+		}
+	}
+	return result
+}
+
+// annotateIPFamilies sets IPFamilies on every port in infos to the families found across
+// podIPs. Container ports aren't family-specific, so a port reachable at all is reachable on
+// every family the pod has an IP in.
+func annotateIPFamilies(infos []PortInfo, podIPs []corev1.PodIP) {
+	if len(podIPs) == 0 {
+		return
+	}
+	var families []string
+	for _, podIP := range podIPs {
+		parsed := net.ParseIP(podIP.IP)
+		if parsed == nil {
+			continue
+		}
+		if parsed.To4() != nil {
+			families = append(families, "IPv4")
+		} else {
+			families = append(families, "IPv6")
+		}
+	}
+	for i := range infos {
+		infos[i].IPFamilies = families
+	}
+}
+
+func portStrings(infos []PortInfo) []string {
+	seen := make(map[string]bool, len(infos))
+	result := make([]string, 0, len(infos))
+	for _, info := range infos {
+		port := strconv.Itoa(int(info.ContainerPort))
+		if seen[port] {
+			continue
+		}
+		seen[port] = true
+		result = append(result, port)
+	}
+	return result
+}
+
+func protocolsForPortInfos(infos []PortInfo) map[string]string {
+	result := make(map[string]string, len(infos))
+	for _, info := range infos {
+		result[strconv.Itoa(int(info.ContainerPort))] = info.Protocol
+	}
+	return result
+}
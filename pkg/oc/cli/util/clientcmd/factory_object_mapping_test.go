@@ -0,0 +1,110 @@
+package clientcmd
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	kapi "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestPortInfosForPodSpecOrdering(t *testing.T) {
+	spec := kapi.PodSpec{
+		Containers: []kapi.Container{
+			{Name: "app", Ports: []kapi.ContainerPort{{Name: "http", ContainerPort: 8080, Protocol: kapi.ProtocolTCP}}},
+		},
+		InitContainers: []kapi.Container{
+			{Name: "init", Ports: []kapi.ContainerPort{{ContainerPort: 9090, Protocol: kapi.ProtocolTCP}}},
+		},
+		EphemeralContainers: []kapi.EphemeralContainer{
+			{EphemeralContainerCommon: kapi.EphemeralContainerCommon{
+				Name:  "debug",
+				Ports: []kapi.ContainerPort{{ContainerPort: 7070, Protocol: kapi.ProtocolTCP}},
+			}},
+		},
+	}
+
+	infos := portInfosForPodSpec(spec)
+
+	got := make([]int32, 0, len(infos))
+	for _, info := range infos {
+		got = append(got, info.ContainerPort)
+	}
+	want := []int32{8080, 9090, 7070}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("portInfosForPodSpec order = %v, want %v (containers before init before ephemeral)", got, want)
+	}
+}
+
+func TestPortStringsDedupes(t *testing.T) {
+	infos := []PortInfo{
+		{ContainerPort: 8080},
+		{ContainerPort: 9090},
+		{ContainerPort: 8080},
+	}
+
+	got := portStrings(infos)
+	want := []string{"8080", "9090"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("portStrings() = %v, want %v", got, want)
+	}
+}
+
+func TestAnnotateIPFamiliesDualStack(t *testing.T) {
+	infos := []PortInfo{{ContainerPort: 8080}}
+	podIPs := []corev1.PodIP{{IP: "10.0.0.1"}, {IP: "fd00::1"}}
+
+	annotateIPFamilies(infos, podIPs)
+
+	want := []string{"IPv4", "IPv6"}
+	if !reflect.DeepEqual(infos[0].IPFamilies, want) {
+		t.Errorf("IPFamilies = %v, want %v", infos[0].IPFamilies, want)
+	}
+}
+
+func TestAnnotateIPFamiliesNoPodIPs(t *testing.T) {
+	infos := []PortInfo{{ContainerPort: 8080}}
+
+	annotateIPFamilies(infos, nil)
+
+	if infos[0].IPFamilies != nil {
+		t.Errorf("IPFamilies = %v, want nil when no podIPs are set", infos[0].IPFamilies)
+	}
+}
+
+func TestPreferredTargetPort(t *testing.T) {
+	tests := []struct {
+		name  string
+		infos []PortInfo
+		want  *int32
+	}{
+		{name: "empty", infos: nil, want: nil},
+		{
+			name:  "no named ports falls back to first",
+			infos: []PortInfo{{ContainerPort: 8080}, {ContainerPort: 9090}},
+			want:  int32Ptr(8080),
+		},
+		{
+			name:  "named port preferred over earlier unnamed port",
+			infos: []PortInfo{{ContainerPort: 8080}, {Name: "https", ContainerPort: 8443}},
+			want:  int32Ptr(8443),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := PreferredTargetPort(tt.infos)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("PreferredTargetPort() = %v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.ContainerPort != *tt.want {
+				t.Fatalf("PreferredTargetPort() = %v, want ContainerPort %d", got, *tt.want)
+			}
+		})
+	}
+}
+
+func int32Ptr(i int32) *int32 { return &i }
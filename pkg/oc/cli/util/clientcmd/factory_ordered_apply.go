@@ -0,0 +1,318 @@
+package clientcmd
+
+import (
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	appsapiv1 "github.com/openshift/api/apps/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	appsapi "github.com/openshift/origin/pkg/apps/apis/apps"
+	routeapi "github.com/openshift/origin/pkg/route/apis/route"
+)
+
+// applyTier groups the GroupKinds that make up one step of an ordered apply. Resources in
+// an earlier tier are waited on to become ready, where a readiness predicate exists, before
+// resources in the next tier are submitted.
+type applyTier struct {
+	name  string
+	kinds []schema.GroupKind
+}
+
+var applyTiers = []applyTier{
+	{name: "namespaces", kinds: []schema.GroupKind{{Kind: "Namespace"}}},
+	{name: "crds", kinds: []schema.GroupKind{{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}}},
+	{name: "rbac", kinds: []schema.GroupKind{
+		{Kind: "ServiceAccount"},
+		{Group: "rbac.authorization.k8s.io", Kind: "Role"},
+		{Group: "rbac.authorization.k8s.io", Kind: "RoleBinding"},
+	}},
+	{name: "config", kinds: []schema.GroupKind{{Kind: "ConfigMap"}, {Kind: "Secret"}}},
+	{name: "storage", kinds: []schema.GroupKind{{Kind: "PersistentVolumeClaim"}, {Kind: "Service"}}},
+	{name: "workloads", kinds: []schema.GroupKind{
+		{Group: "apps", Kind: "Deployment"},
+		{Group: "apps.openshift.io", Kind: "DeploymentConfig"},
+		{Kind: "DeploymentConfig"},
+		{Group: "apps", Kind: "StatefulSet"},
+	}},
+	{name: "routes", kinds: []schema.GroupKind{
+		{Group: "route.openshift.io", Kind: "Route"},
+		{Kind: "Route"},
+		{Group: "extensions", Kind: "Ingress"},
+		{Group: "networking.k8s.io", Kind: "Ingress"},
+	}},
+	{name: "jobs", kinds: []schema.GroupKind{{Group: "batch", Kind: "Job"}, {Group: "batch", Kind: "CronJob"}}},
+}
+
+// tierForKind returns the index of the tier a GroupKind belongs to, or len(applyTiers) for
+// anything unrecognized, so unordered kinds still apply - just last, and all together.
+func tierForKind(kind schema.GroupKind) int {
+	for i, tier := range applyTiers {
+		for _, k := range tier.kinds {
+			if k == kind {
+				return i
+			}
+		}
+	}
+	return len(applyTiers)
+}
+
+// OrderedApplyOptions configures how OrderedApplier waits between tiers.
+type OrderedApplyOptions struct {
+	// ReadyTimeout bounds how long Apply waits for a single tier to become ready.
+	ReadyTimeout time.Duration
+	// RetryBackoff is the initial delay between readiness polls of a tier; it doubles on
+	// each retry up to ReadyTimeout.
+	RetryBackoff time.Duration
+}
+
+func (o OrderedApplyOptions) withDefaults() OrderedApplyOptions {
+	if o.ReadyTimeout <= 0 {
+		o.ReadyTimeout = 5 * time.Minute
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = time.Second
+	}
+	return o
+}
+
+// ResourceStatus describes what became of a single resource.Info during an ordered apply.
+type ResourceStatus struct {
+	Name      string
+	Namespace string
+	Kind      string
+	Ready     bool
+	TimedOut  bool
+	Err       error
+}
+
+// OrderedApplyReport is the structured result of an OrderedApplier.Apply call.
+type OrderedApplyReport struct {
+	Ready    []ResourceStatus
+	TimedOut []ResourceStatus
+	Errored  []ResourceStatus
+}
+
+// OrderedApplier applies a set of resource.Info in dependency order (namespaces, then CRDs,
+// then RBAC, then config, then storage/services, then workloads, then routes/ingress, then
+// jobs), waiting for each tier to become ready before moving on to the next.
+type OrderedApplier interface {
+	Apply(infos []*resource.Info, options OrderedApplyOptions) (*OrderedApplyReport, error)
+}
+
+func NewOrderedApplier() OrderedApplier {
+	return &orderedApplier{}
+}
+
+type orderedApplier struct{}
+
+func (a *orderedApplier) Apply(infos []*resource.Info, options OrderedApplyOptions) (*OrderedApplyReport, error) {
+	options = options.withDefaults()
+
+	tiers := make(map[int][]*resource.Info)
+	for _, info := range infos {
+		idx := tierForKind(info.Object.GetObjectKind().GroupVersionKind().GroupKind())
+		tiers[idx] = append(tiers[idx], info)
+	}
+
+	report := &OrderedApplyReport{}
+	for i := 0; i <= len(applyTiers); i++ {
+		tierInfos := tiers[i]
+		if len(tierInfos) == 0 {
+			continue
+		}
+		if err := createTier(tierInfos); err != nil {
+			return report, err
+		}
+		waitForTierReady(tierInfos, options, report)
+	}
+	return report, nil
+}
+
+// createTier submits every resource in a tier to the server before the tier is waited on.
+// Resources that already exist are left alone - OrderedApplier sequences and waits, it
+// doesn't reconcile an existing object's spec.
+func createTier(infos []*resource.Info) error {
+	for _, info := range infos {
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		obj, err := helper.Create(info.Namespace, true, info.Object, nil)
+		if err != nil {
+			if apierrors.IsAlreadyExists(err) {
+				continue
+			}
+			return fmt.Errorf("creating %s %q: %v", info.Mapping.GroupVersionKind.Kind, info.Name, err)
+		}
+		if err := info.Refresh(obj, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pendingResource tracks a resource.Info still being waited on, plus the last error (if any)
+// hit while refreshing it, so that error can be folded into the eventual report without
+// short-circuiting the backoff loop the first time a Get fails.
+type pendingResource struct {
+	info    *resource.Info
+	lastErr error
+}
+
+// waitForTierReady polls the readiness of every resource in a tier until they are all ready
+// or options.ReadyTimeout elapses, recording the outcome of each into report. A NotFound Get
+// error - expected briefly while a just-created object becomes visible - is treated the same
+// as "not ready yet" and retried with the rest of the tier's backoff, only turning into a
+// report.Errored entry if the deadline passes while still failing. Any other Get error (for
+// example Forbidden) is not transient and is reported immediately instead of burning the
+// tier's whole timeout on something that will never resolve.
+func waitForTierReady(infos []*resource.Info, options OrderedApplyOptions, report *OrderedApplyReport) {
+	backoff := options.RetryBackoff
+	deadline := time.Now().Add(options.ReadyTimeout)
+
+	pending := make([]*pendingResource, 0, len(infos))
+	for _, info := range infos {
+		pending = append(pending, &pendingResource{info: info})
+	}
+
+	for len(pending) > 0 {
+		var stillPending []*pendingResource
+		for _, p := range pending {
+			if err := p.info.Get(); err != nil {
+				if !apierrors.IsNotFound(err) {
+					report.Errored = append(report.Errored, ResourceStatus{
+						Name:      p.info.Name,
+						Namespace: p.info.Namespace,
+						Kind:      p.info.Object.GetObjectKind().GroupVersionKind().Kind,
+						Err:       err,
+					})
+					continue
+				}
+				p.lastErr = err
+				stillPending = append(stillPending, p)
+				continue
+			}
+			p.lastErr = nil
+
+			if isResourceReady(p.info.Object) {
+				report.Ready = append(report.Ready, ResourceStatus{
+					Name:      p.info.Name,
+					Namespace: p.info.Namespace,
+					Kind:      p.info.Object.GetObjectKind().GroupVersionKind().Kind,
+					Ready:     true,
+				})
+				continue
+			}
+			stillPending = append(stillPending, p)
+		}
+		pending = stillPending
+		if len(pending) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			for _, p := range pending {
+				status := ResourceStatus{
+					Name:      p.info.Name,
+					Namespace: p.info.Namespace,
+					Kind:      p.info.Object.GetObjectKind().GroupVersionKind().Kind,
+				}
+				if p.lastErr != nil {
+					status.Err = p.lastErr
+					report.Errored = append(report.Errored, status)
+				} else {
+					status.TimedOut = true
+					report.TimedOut = append(report.TimedOut, status)
+				}
+			}
+			return
+		}
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > options.ReadyTimeout {
+			backoff = options.ReadyTimeout
+		}
+	}
+}
+
+// isResourceReady reports whether obj has reached steady state. Kinds without a known
+// readiness predicate are considered ready as soon as resource.Info.Get succeeds.
+func isResourceReady(obj interface{}) bool {
+	switch t := obj.(type) {
+	case *appsapi.DeploymentConfig:
+		return t.Status.LatestVersion > 0 &&
+			t.Status.AvailableReplicas >= t.Spec.Replicas-maxUnavailableReplicas(t)
+	case *appsapiv1.DeploymentConfig:
+		return t.Status.LatestVersion > 0 &&
+			t.Status.AvailableReplicas >= t.Spec.Replicas-maxUnavailableReplicasV1(t)
+	case *routeapi.Route:
+		return routeAdmitted(t.Status.Ingress)
+	case *routev1.Route:
+		return routeAdmittedV1(t.Status.Ingress)
+	default:
+		return true
+	}
+}
+
+func maxUnavailableReplicas(dc *appsapi.DeploymentConfig) int32 {
+	params := dc.Spec.Strategy.RollingParams
+	if params == nil || params.MaxUnavailable == nil {
+		return 0
+	}
+	value, _ := intstr.GetValueFromIntOrPercent(params.MaxUnavailable, int(dc.Spec.Replicas), false)
+	return int32(value)
+}
+
+func maxUnavailableReplicasV1(dc *appsapiv1.DeploymentConfig) int32 {
+	params := dc.Spec.Strategy.RollingParams
+	if params == nil || params.MaxUnavailable == nil {
+		return 0
+	}
+	value, _ := intstr.GetValueFromIntOrPercent(params.MaxUnavailable, int(dc.Spec.Replicas), false)
+	return int32(value)
+}
+
+func routeAdmitted(ingresses []routeapi.RouteIngress) bool {
+	if len(ingresses) == 0 {
+		return false
+	}
+	for _, ingress := range ingresses {
+		if !ingressAdmitted(ingress.Conditions) {
+			return false
+		}
+	}
+	return true
+}
+
+func routeAdmittedV1(ingresses []routev1.RouteIngress) bool {
+	if len(ingresses) == 0 {
+		return false
+	}
+	for _, ingress := range ingresses {
+		if !ingressAdmitted(ingress.Conditions) {
+			return false
+		}
+	}
+	return true
+}
+
+// ingressAdmitted reports whether a RouteIngress's conditions contain Admitted=True. It's
+// written against the condition's string type/status rather than the generated constants so
+// it works identically for the internal and v1 route APIs.
+func ingressAdmitted(conditions interface{}) bool {
+	switch conds := conditions.(type) {
+	case []routeapi.RouteIngressCondition:
+		for _, c := range conds {
+			if string(c.Type) == "Admitted" && string(c.Status) == "True" {
+				return true
+			}
+		}
+	case []routev1.RouteIngressCondition:
+		for _, c := range conds {
+			if string(c.Type) == "Admitted" && string(c.Status) == "True" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package clientcmd
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	routev1 "github.com/openshift/api/route/v1"
+	appsapi "github.com/openshift/origin/pkg/apps/apis/apps"
+)
+
+func TestTierForKind(t *testing.T) {
+	tests := []struct {
+		kind schema.GroupKind
+		want int
+	}{
+		{kind: schema.GroupKind{Kind: "Namespace"}, want: 0},
+		{kind: schema.GroupKind{Kind: "ConfigMap"}, want: 3},
+		{kind: schema.GroupKind{Group: "apps", Kind: "Deployment"}, want: 5},
+		{kind: schema.GroupKind{Group: "route.openshift.io", Kind: "Route"}, want: 6},
+		{kind: schema.GroupKind{Group: "batch", Kind: "Job"}, want: 7},
+		{kind: schema.GroupKind{Kind: "SomeUnrecognizedKind"}, want: len(applyTiers)},
+	}
+
+	for _, tt := range tests {
+		if got := tierForKind(tt.kind); got != tt.want {
+			t.Errorf("tierForKind(%v) = %d, want %d", tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestIsResourceReadyDeploymentConfig(t *testing.T) {
+	dc := &appsapi.DeploymentConfig{
+		Spec: appsapi.DeploymentConfigSpec{Replicas: 3},
+		Status: appsapi.DeploymentConfigStatus{
+			LatestVersion:     1,
+			AvailableReplicas: 3,
+		},
+	}
+	if !isResourceReady(dc) {
+		t.Errorf("isResourceReady() = false, want true for a fully available DC")
+	}
+
+	notRolledOut := &appsapi.DeploymentConfig{
+		Spec:   appsapi.DeploymentConfigSpec{Replicas: 3},
+		Status: appsapi.DeploymentConfigStatus{LatestVersion: 0, AvailableReplicas: 0},
+	}
+	if isResourceReady(notRolledOut) {
+		t.Errorf("isResourceReady() = true, want false before the first rollout (LatestVersion == 0)")
+	}
+}
+
+func TestMaxUnavailableReplicas(t *testing.T) {
+	dc := &appsapi.DeploymentConfig{
+		Spec: appsapi.DeploymentConfigSpec{
+			Replicas: 10,
+			Strategy: appsapi.DeploymentStrategy{
+				RollingParams: &appsapi.RollingDeploymentStrategyParams{
+					MaxUnavailable: intOrStringPtr(intstr.FromString("25%")),
+				},
+			},
+		},
+	}
+	if got, want := maxUnavailableReplicas(dc), int32(2); got != want {
+		t.Errorf("maxUnavailableReplicas() = %d, want %d", got, want)
+	}
+
+	noParams := &appsapi.DeploymentConfig{Spec: appsapi.DeploymentConfigSpec{Replicas: 10}}
+	if got := maxUnavailableReplicas(noParams); got != 0 {
+		t.Errorf("maxUnavailableReplicas() = %d, want 0 when RollingParams is nil", got)
+	}
+}
+
+func TestRouteAdmittedV1(t *testing.T) {
+	tests := []struct {
+		name      string
+		ingresses []routev1.RouteIngress
+		want      bool
+	}{
+		{name: "no ingresses", ingresses: nil, want: false},
+		{
+			name: "admitted",
+			ingresses: []routev1.RouteIngress{
+				{Conditions: []routev1.RouteIngressCondition{{Type: "Admitted", Status: "True"}}},
+			},
+			want: true,
+		},
+		{
+			name: "not admitted",
+			ingresses: []routev1.RouteIngress{
+				{Conditions: []routev1.RouteIngressCondition{{Type: "Admitted", Status: "False"}}},
+			},
+			want: false,
+		},
+		{
+			name: "one of several ingresses not admitted",
+			ingresses: []routev1.RouteIngress{
+				{Conditions: []routev1.RouteIngressCondition{{Type: "Admitted", Status: "True"}}},
+				{Conditions: []routev1.RouteIngressCondition{{Type: "Admitted", Status: "False"}}},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := routeAdmittedV1(tt.ingresses); got != tt.want {
+				t.Errorf("routeAdmittedV1() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func intOrStringPtr(v intstr.IntOrString) *intstr.IntOrString { return &v }
@@ -0,0 +1,57 @@
+package clientcmd
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	appsapiv1 "github.com/openshift/api/apps/v1"
+)
+
+func TestPodDisruptionBudgetSelectorForObjectDeploymentConfig(t *testing.T) {
+	f := &ring1Factory{}
+	dc := &appsapiv1.DeploymentConfig{
+		Spec: appsapiv1.DeploymentConfigSpec{Selector: map[string]string{"app": "foo"}},
+	}
+
+	got, err := f.PodDisruptionBudgetSelectorForObject(dc)
+	if err != nil {
+		t.Fatalf("PodDisruptionBudgetSelectorForObject() error = %v", err)
+	}
+	want := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PodDisruptionBudgetSelectorForObject() = %#v, want %#v", got, want)
+	}
+}
+
+func TestPodDisruptionBudgetSelectorForObjectService(t *testing.T) {
+	f := &ring1Factory{}
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "foo"}},
+	}
+
+	got, err := f.PodDisruptionBudgetSelectorForObject(svc)
+	if err != nil {
+		t.Fatalf("PodDisruptionBudgetSelectorForObject() error = %v", err)
+	}
+	// metav1.ParseToLabelSelector always returns a non-nil, empty MatchExpressions slice.
+	want := &metav1.LabelSelector{
+		MatchLabels:      map[string]string{"app": "foo"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("PodDisruptionBudgetSelectorForObject() = %#v, want %#v", got, want)
+	}
+}
+
+func TestPodDisruptionBudgetSelectorForObjectUnselectableKind(t *testing.T) {
+	f := &ring1Factory{}
+	cm := &corev1.ConfigMap{}
+
+	if _, err := f.PodDisruptionBudgetSelectorForObject(cm); err == nil {
+		t.Errorf("PodDisruptionBudgetSelectorForObject() error = nil, want an error for a kind with no pod selector")
+	}
+}